@@ -0,0 +1,27 @@
+package v1alpha1
+
+// Condition types set on ManagedServiceAccountStatus.Conditions.
+const (
+	// ConditionTypeTokenValidated indicates whether the most recently minted
+	// token was confirmed to authenticate against the spoke API server
+	// before being published to the hub. On a spoke that does not serve
+	// SelfSubjectReview the probe is skipped and the condition is set to
+	// True with a message saying the token was not verified.
+	ConditionTypeTokenValidated = "TokenValidated"
+
+	// ConditionTypeServiceAccountCreated indicates whether the native
+	// ServiceAccount backing this ManagedServiceAccount exists on the
+	// managed cluster.
+	ConditionTypeServiceAccountCreated = "ServiceAccountCreated"
+
+	// ConditionTypeTokenRotated is set to True when a reconciliation
+	// publishes a newly obtained token. It is not reset when a later
+	// reconciliation keeps the existing token.
+	ConditionTypeTokenRotated = "TokenRotated"
+
+	// ConditionTypeTokenReported indicates whether a validated token has
+	// been reported to ManagedServiceAccountStatus. Its LastTransitionTime
+	// is the source of truth for when the current token was reported, used
+	// to decide when the next rotation is due.
+	ConditionTypeTokenReported = "TokenReported"
+)
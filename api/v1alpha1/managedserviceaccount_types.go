@@ -0,0 +1,94 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ManagedServiceAccountSpec defines the desired state of ManagedServiceAccount
+type ManagedServiceAccountSpec struct {
+	// Rotation is the policy for rotating the generated service account token.
+	// +optional
+	Rotation ManagedServiceAccountRotation `json:"rotation,omitempty"`
+
+	// Audiences are the intended audiences of the projected service account
+	// token, as defined in the "aud" claim of the token. If unset, the
+	// audiences will default to the audience of kube-apiserver on the
+	// managed cluster.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+
+	// BoundObjectRef refers to an object on the managed cluster that the
+	// token should be bound to. The service account token minted for this
+	// ManagedServiceAccount will be invalidated when the bound object is
+	// deleted. Only Secret and Pod are supported.
+	// +optional
+	BoundObjectRef *corev1.ObjectReference `json:"boundObjectRef,omitempty"`
+}
+
+// ManagedServiceAccountRotation defines the rotation policy of the
+// service account token.
+type ManagedServiceAccountRotation struct {
+	// Disabled indicates whether the token rotation should be disabled for
+	// the ManagedServiceAccount. Token rotation is enabled by default; set
+	// this to true to fall back to a legacy Secret-backed token instead.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Validity is the duration for which the signed service account token
+	// is valid.
+	// +optional
+	Validity metav1.Duration `json:"validity,omitempty"`
+}
+
+// ManagedServiceAccountStatus defines the observed state of ManagedServiceAccount
+type ManagedServiceAccountStatus struct {
+	// ExpirationTimestamp is the time when the token will expire.
+	// +optional
+	ExpirationTimestamp *metav1.Time `json:"expirationTimestamp,omitempty"`
+
+	// Token is the content of the created token secret.
+	// +optional
+	Token string `json:"token,omitempty"`
+
+	// CACertificateData holds the CA certificate of the managed cluster's
+	// kube-apiserver used to validate the token.
+	// +optional
+	CACertificateData []byte `json:"caCertificateData,omitempty"`
+
+	// TokenAudiences records the effective audiences that were requested
+	// when the current token was minted.
+	// +optional
+	TokenAudiences []string `json:"tokenAudiences,omitempty"`
+
+	// Conditions hold the latest observations of the ManagedServiceAccount's state.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ManagedServiceAccount is the Schema for the managedserviceaccounts API
+type ManagedServiceAccount struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ManagedServiceAccountSpec   `json:"spec,omitempty"`
+	Status ManagedServiceAccountStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ManagedServiceAccountList contains a list of ManagedServiceAccount
+type ManagedServiceAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ManagedServiceAccount `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ManagedServiceAccount{}, &ManagedServiceAccountList{})
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	authv1alpha1 "open-cluster-management.io/managed-serviceaccount/api/v1alpha1"
+	"open-cluster-management.io/managed-serviceaccount/pkg/common"
+
+	"github.com/pkg/errors"
+	authv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// legacySecretFinalizer is set on a ManagedServiceAccount while it owns a
+	// legacy kubernetes.io/service-account-token Secret on the spoke, so the
+	// secret can be garbage-collected when the ManagedServiceAccount is deleted.
+	legacySecretFinalizer = "authentication.open-cluster-management.io/legacy-secret-cleanup"
+
+	legacySecretPollInterval = time.Second * 2
+	legacySecretPollTimeout  = time.Second * 30
+)
+
+// usesLegacyToken reports whether managed should be served by a
+// Secret-backed service account token instead of the TokenRequest API,
+// mirroring Rancher's EnsureSecretForServiceAccount pattern for spokes that
+// predate projected service account tokens.
+func (r *TokenReconciler) usesLegacyToken(managed *authv1alpha1.ManagedServiceAccount) bool {
+	return !r.TokenRequestSupported || managed.Spec.Rotation.Disabled
+}
+
+// detectTokenRequestSupport probes the spoke cluster's discovery document for
+// the serviceaccounts/token subresource that backs the TokenRequest API.
+func (r *TokenReconciler) detectTokenRequestSupport() (bool, error) {
+	return r.discoverAPIResource(authv1.SchemeGroupVersion.String(), "serviceaccounts/token")
+}
+
+// detectSelfSubjectReviewSupport probes the spoke cluster's discovery
+// document for the selfsubjectreviews resource used to validate minted
+// tokens. SelfSubjectReview only reached GA in Kubernetes 1.30, so older
+// spokes are expected to lack it.
+func (r *TokenReconciler) detectSelfSubjectReviewSupport() (bool, error) {
+	return r.discoverAPIResource(authv1.SchemeGroupVersion.String(), "selfsubjectreviews")
+}
+
+func (r *TokenReconciler) discoverAPIResource(groupVersion, name string) (bool, error) {
+	resources, err := r.SpokeNativeClient.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to discover spoke API resources")
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func legacySecretName(managed *authv1alpha1.ManagedServiceAccount) string {
+	return "msa-" + managed.Name + "-token"
+}
+
+// createLegacyToken ensures a kubernetes.io/service-account-token Secret
+// exists in SpokeNamespace for managed's service account, then waits for
+// kube-controller-manager to populate its token and CA data.
+func (r *TokenReconciler) createLegacyToken(ctx context.Context, managed *authv1alpha1.ManagedServiceAccount) (string, []byte, error) {
+	secrets := r.SpokeNativeClient.CoreV1().Secrets(r.SpokeNamespace)
+	name := legacySecretName(managed)
+
+	if _, err := secrets.Get(ctx, name, metav1.GetOptions{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "", nil, errors.Wrapf(err, "failed to get legacy token secret %q", name)
+		}
+		if _, err := secrets.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: r.SpokeNamespace,
+				Annotations: map[string]string{
+					corev1.ServiceAccountNameKey: managed.Name,
+				},
+				Labels: map[string]string{
+					common.LabelKeyIsManagedServiceAccount: "true",
+				},
+			},
+			Type: corev1.SecretTypeServiceAccountToken,
+		}, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return "", nil, errors.Wrapf(err, "failed to create legacy token secret %q", name)
+		}
+	}
+
+	var secret *corev1.Secret
+	if err := wait.PollImmediate(legacySecretPollInterval, legacySecretPollTimeout, func() (bool, error) {
+		latest, err := secrets.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		secret = latest
+		return len(secret.Data[corev1.ServiceAccountTokenKey]) > 0, nil
+	}); err != nil {
+		return "", nil, errors.Wrapf(err, "timed out waiting for kube-controller-manager to populate secret %q", name)
+	}
+
+	return string(secret.Data[corev1.ServiceAccountTokenKey]), secret.Data[corev1.ServiceAccountRootCAKey], nil
+}
+
+// reconcileDelete garbage-collects the legacy token secret owned by managed,
+// if any, and removes the finalizer so the ManagedServiceAccount can finish
+// deleting on the hub.
+func (r *TokenReconciler) reconcileDelete(ctx context.Context, managed *authv1alpha1.ManagedServiceAccount) (reconcile.Result, error) {
+	if !controllerutil.ContainsFinalizer(managed, legacySecretFinalizer) {
+		return reconcile.Result{}, nil
+	}
+	if err := r.removeLegacySecret(ctx, managed); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// removeLegacySecret garbage-collects the legacy token secret owned by
+// managed, if any, and removes legacySecretFinalizer. It is used both when
+// the ManagedServiceAccount is being deleted and when it moves off the
+// legacy path while still alive (spoke upgraded past 1.22, or
+// Spec.Rotation.Disabled flipped back to false), so the stale secret
+// doesn't outlive the mode that created it.
+func (r *TokenReconciler) removeLegacySecret(ctx context.Context, managed *authv1alpha1.ManagedServiceAccount) error {
+	name := legacySecretName(managed)
+	if err := r.SpokeNativeClient.CoreV1().Secrets(r.SpokeNamespace).
+		Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to garbage collect legacy token secret %q", name)
+	}
+
+	controllerutil.RemoveFinalizer(managed, legacySecretFinalizer)
+	if err := r.HubClient.Update(ctx, managed); err != nil {
+		return errors.Wrapf(err, "failed to remove legacy secret finalizer")
+	}
+	return nil
+}
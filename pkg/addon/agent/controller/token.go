@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"time"
 
@@ -12,12 +13,17 @@ import (
 	authv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -31,6 +37,23 @@ type TokenReconciler struct {
 	SpokeNativeClient kubernetes.Interface
 	SpokeClientConfig *rest.Config
 	SpokeNamespace    string
+	TokenGetter       *TokenGetter
+
+	// TokenRequestSupported records whether the spoke cluster's kube-apiserver
+	// exposes the serviceaccounts/token subresource. It is detected once via
+	// discovery in SetupWithManager.
+	TokenRequestSupported bool
+
+	// SelfSubjectReviewSupported records whether the spoke cluster's
+	// kube-apiserver exposes the selfsubjectreviews resource used to
+	// validate minted tokens. It is detected once via discovery in
+	// SetupWithManager; validateToken is skipped when it is false.
+	SelfSubjectReviewSupported bool
+
+	// Recorder emits Events on the ManagedServiceAccount for meaningful
+	// transitions (token rotated, token request failed, ...), so operators
+	// can see rotation history without reading logs.
+	Recorder record.EventRecorder
 }
 
 func (r *TokenReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -45,18 +68,54 @@ func (r *TokenReconciler) Reconcile(ctx context.Context, request reconcile.Reque
 		return reconcile.Result{}, nil
 	}
 
-	if err := r.ensureServiceAccount(managed); err != nil {
+	if !managed.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, managed)
+	}
+
+	created, err := r.ensureServiceAccount(managed)
+	if err != nil {
 		return reconcile.Result{}, errors.Wrapf(err, "failed to ensure service account")
 	}
+	if created {
+		r.Recorder.Eventf(managed, corev1.EventTypeNormal, "ServiceAccountCreated",
+			"created service account %s/%s on the managed cluster", r.SpokeNamespace, managed.Name)
+		if err := r.patchStatus(ctx, request.NamespacedName, func(status *authv1alpha1.ManagedServiceAccountStatus) {
+			apimeta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:    authv1alpha1.ConditionTypeServiceAccountCreated,
+				Status:  metav1.ConditionTrue,
+				Reason:  "ServiceAccountCreated",
+				Message: fmt.Sprintf("service account %s/%s created on the managed cluster", r.SpokeNamespace, managed.Name),
+			})
+		}); err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to record service account creation")
+		}
+		// patchStatus above updated managed's resourceVersion on the hub
+		// without updating our copy; re-Get it so the finalizer mutation
+		// below doesn't 409 against the value it just patched.
+		if err := r.HubClient.Get(ctx, request.NamespacedName, managed); err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to refresh managed service account after status patch")
+		}
+	}
 
-	if !r.shouldCreateToken(managed) {
-		logger.Info("Skipped creating token")
-		return reconcile.Result{}, nil
+	legacy := r.usesLegacyToken(managed)
+	switch {
+	case legacy && !controllerutil.ContainsFinalizer(managed, legacySecretFinalizer):
+		controllerutil.AddFinalizer(managed, legacySecretFinalizer)
+		if err := r.HubClient.Update(ctx, managed); err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to add legacy secret finalizer")
+		}
+	case !legacy && controllerutil.ContainsFinalizer(managed, legacySecretFinalizer):
+		// managed moved off the legacy path while still alive (spoke
+		// upgraded past 1.22, or rotation re-enabled); don't leave the old
+		// Secret abandoned on the spoke.
+		if err := r.removeLegacySecret(ctx, managed); err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to garbage collect stale legacy token secret")
+		}
 	}
 
-	token, expiring, err := r.createToken(managed)
-	if err != nil {
-		return reconcile.Result{}, errors.Wrapf(err, "failed to request token for service-account")
+	if !r.shouldCreateToken(managed, legacy) {
+		logger.Info("Skipped creating token")
+		return reconcile.Result{}, nil
 	}
 
 	caData := r.SpokeClientConfig.CAData
@@ -64,34 +123,143 @@ func (r *TokenReconciler) Reconcile(ctx context.Context, request reconcile.Reque
 		var err error
 		caData, err = ioutil.ReadFile(r.SpokeClientConfig.CAFile)
 		if err != nil {
+			r.Recorder.Eventf(managed, corev1.EventTypeWarning, "CADataMissing", "failed to read CA data: %v", err)
 			return reconcile.Result{}, errors.Wrapf(err, "failed to read CA data from file")
 		}
 	}
 
-	status := authv1alpha1.ManagedServiceAccountStatus{
-		Token:               token,
-		ExpirationTimestamp: &expiring,
-		CACertificateData:   caData,
+	var (
+		token     string
+		expiring  metav1.Time
+		audiences []string
+	)
+	if legacy {
+		legacyToken, legacyCA, err := r.createLegacyToken(ctx, managed)
+		if err != nil {
+			r.Recorder.Eventf(managed, corev1.EventTypeWarning, "TokenRequestFailed", "failed to ensure legacy token secret: %v", err)
+			return reconcile.Result{}, errors.Wrapf(err, "failed to ensure legacy service-account-token secret")
+		}
+		token = legacyToken
+		if len(legacyCA) > 0 {
+			caData = legacyCA
+		}
+	} else {
+		mintedToken, mintedExpiring, mintedAudiences, rotated, err := r.createToken(ctx, managed)
+		if err != nil {
+			r.Recorder.Eventf(managed, corev1.EventTypeWarning, "TokenRequestFailed", "failed to mint token: %v", err)
+			return reconcile.Result{}, errors.Wrapf(err, "failed to request token for service-account")
+		}
+		if !rotated {
+			logger.Info("Cached token still valid, skipped updating status")
+			return reconcile.Result{}, nil
+		}
+		token, expiring, audiences = mintedToken, mintedExpiring, mintedAudiences
 	}
 
-	munged := managed.DeepCopy()
-	munged.Status = status
-	if err := r.HubClient.Status().Update(context.TODO(), munged); err != nil {
+	if err := r.validateToken(ctx, managed, token, caData); err != nil {
+		logger.Info("Minted token failed validation", "error", err.Error())
+		if condErr := r.patchStatus(ctx, request.NamespacedName, func(status *authv1alpha1.ManagedServiceAccountStatus) {
+			apimeta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:    authv1alpha1.ConditionTypeTokenValidated,
+				Status:  metav1.ConditionFalse,
+				Reason:  "TokenValidationFailed",
+				Message: err.Error(),
+			})
+		}); condErr != nil {
+			return reconcile.Result{}, errors.Wrapf(condErr, "failed to record token validation failure")
+		}
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	validatedMessage := "the minted token was verified against the spoke API server"
+	if !r.SelfSubjectReviewSupported {
+		validatedMessage = "the spoke API server does not serve SelfSubjectReview; the token was not verified"
+	}
+	if err := r.patchStatus(ctx, request.NamespacedName, func(status *authv1alpha1.ManagedServiceAccountStatus) {
+		status.Token = token
+		status.CACertificateData = caData
+		status.TokenAudiences = audiences
+		if !expiring.IsZero() {
+			status.ExpirationTimestamp = &expiring
+		}
+		apimeta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:    authv1alpha1.ConditionTypeTokenValidated,
+			Status:  metav1.ConditionTrue,
+			Reason:  "TokenValidated",
+			Message: validatedMessage,
+		})
+		apimeta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:    authv1alpha1.ConditionTypeTokenRotated,
+			Status:  metav1.ConditionTrue,
+			Reason:  "TokenRotated",
+			Message: "a new token was minted for the service account",
+		})
+		// Drop the old condition first so its LastTransitionTime is reset to
+		// the time this token was reported.
+		apimeta.RemoveStatusCondition(&status.Conditions, authv1alpha1.ConditionTypeTokenReported)
+		apimeta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:    authv1alpha1.ConditionTypeTokenReported,
+			Status:  metav1.ConditionTrue,
+			Reason:  "TokenReported",
+			Message: "the token was reported to the hub",
+		})
+	}); err != nil {
+		if apierrors.IsConflict(err) {
+			logger.Info("Conflict updating status, requeuing without re-minting", "error", err.Error())
+			return reconcile.Result{Requeue: true}, nil
+		}
 		return reconcile.Result{}, errors.Wrapf(err, "failed to update status")
 	}
 
+	r.Recorder.Eventf(managed, corev1.EventTypeNormal, "TokenRotated",
+		"rotated token for service account %s/%s", r.SpokeNamespace, managed.Name)
 	logger.Info("Refreshed token")
 	return reconcile.Result{}, nil
 }
 
+// patchStatus re-fetches the ManagedServiceAccount from the hub, applies
+// mutate to its status and patches only the fields mutate changed back with
+// a JSON merge patch, retrying on optimistic-concurrency conflicts so a
+// status write from another controller doesn't get stomped.
+func (r *TokenReconciler) patchStatus(ctx context.Context, key types.NamespacedName, mutate func(*authv1alpha1.ManagedServiceAccountStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &authv1alpha1.ManagedServiceAccount{}
+		if err := r.HubClient.Get(ctx, key, latest); err != nil {
+			return err
+		}
+		original := latest.DeepCopy()
+		mutate(&latest.Status)
+		return r.HubClient.Status().Patch(ctx, latest, client.MergeFrom(original))
+	})
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *TokenReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.TokenGetter == nil {
+		r.TokenGetter = &TokenGetter{SpokeNativeClient: r.SpokeNativeClient}
+	}
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("managed-serviceaccount-agent")
+	}
+	supported, err := r.detectTokenRequestSupport()
+	if err != nil {
+		return errors.Wrapf(err, "failed to detect TokenRequest API support on spoke cluster")
+	}
+	r.TokenRequestSupported = supported
+	reviewSupported, err := r.detectSelfSubjectReviewSupport()
+	if err != nil {
+		return errors.Wrapf(err, "failed to detect SelfSubjectReview API support on spoke cluster")
+	}
+	r.SelfSubjectReviewSupported = reviewSupported
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&authv1alpha1.ManagedServiceAccount{}).
 		Complete(r)
 }
 
-func (r *TokenReconciler) ensureServiceAccount(managed *authv1alpha1.ManagedServiceAccount) error {
+// ensureServiceAccount creates the native ServiceAccount backing managed on
+// the spoke cluster if it doesn't already exist, reporting whether it just
+// created it.
+func (r *TokenReconciler) ensureServiceAccount(managed *authv1alpha1.ManagedServiceAccount) (bool, error) {
 	sa := &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: r.SpokeNamespace,
@@ -105,36 +273,104 @@ func (r *TokenReconciler) ensureServiceAccount(managed *authv1alpha1.ManagedServ
 		ServiceAccounts(r.SpokeNamespace).
 		Create(context.TODO(), sa, metav1.CreateOptions{}); err != nil {
 		if !apierrors.IsAlreadyExists(err) {
-			return errors.Wrapf(err, "failed ensuring service account")
+			return false, errors.Wrapf(err, "failed ensuring service account")
 		}
+		return false, nil
 	}
-	return nil
+	return true, nil
 }
 
-func (r *TokenReconciler) shouldCreateToken(managed *authv1alpha1.ManagedServiceAccount) bool {
+func (r *TokenReconciler) shouldCreateToken(managed *authv1alpha1.ManagedServiceAccount, legacy bool) bool {
 	if len(managed.Status.Token) == 0 {
 		return true
 	}
-	now := metav1.Now()
-	refreshThreshold := time.Hour * 24 * 15 // 15d
-	lifetime := managed.Status.ExpirationTimestamp.Sub(now.Time)
-	if lifetime < refreshThreshold {
+	if legacy {
+		// legacy secret-backed tokens are rotated by kube-controller-manager,
+		// not by us; once the secret exists there is nothing left to mint.
+		return false
+	}
+
+	reported := apimeta.FindStatusCondition(managed.Status.Conditions, authv1alpha1.ConditionTypeTokenReported)
+	if reported == nil {
 		return true
 	}
 
-	return false
+	refreshThreshold := time.Hour * 24 * 15 // 15d
+	age := time.Since(reported.LastTransitionTime.Time)
+	remaining := managed.Spec.Rotation.Validity.Duration - age
+	return remaining < refreshThreshold
 }
 
-func (r *TokenReconciler) createToken(managed *authv1alpha1.ManagedServiceAccount) (string, metav1.Time, error) {
+func (r *TokenReconciler) createToken(ctx context.Context, managed *authv1alpha1.ManagedServiceAccount) (string, metav1.Time, []string, bool, error) {
+	boundObjectRef, err := r.resolveBoundObjectRef(managed)
+	if err != nil {
+		return "", metav1.Time{}, nil, false, errors.Wrapf(err, "failed to resolve bound object reference")
+	}
+
 	var expirationSec = int64(managed.Spec.Rotation.Validity.Seconds())
-	tr, err := r.SpokeNativeClient.CoreV1().ServiceAccounts(r.SpokeNamespace).
-		CreateToken(context.TODO(), managed.Name, &authv1.TokenRequest{
-			Spec: authv1.TokenRequestSpec{
-				ExpirationSeconds: &expirationSec,
-			},
-		}, metav1.CreateOptions{})
+	tr := &authv1.TokenRequest{
+		Spec: authv1.TokenRequestSpec{
+			ExpirationSeconds: &expirationSec,
+			Audiences:         managed.Spec.Audiences,
+			BoundObjectRef:    boundObjectRef,
+		},
+	}
+
+	key := types.NamespacedName{Namespace: r.SpokeNamespace, Name: managed.Name}
+	status, rotated, err := r.TokenGetter.Get(ctx, key, tr)
 	if err != nil {
-		return "", metav1.Time{}, err
+		return "", metav1.Time{}, nil, false, err
+	}
+
+	// TokenGetter only reports whether its own cache considers the token
+	// fresh, not whether the hub already has it. If a prior reconcile
+	// minted and cached a token but failed to publish it (validation
+	// failure, non-conflict patch error), the cache would otherwise report
+	// "not rotated" forever and Reconcile would stop publishing a token the
+	// hub never actually received.
+	published := status.Token == managed.Status.Token
+
+	// TokenGetter only ever returns a status minted for tr.Spec (it forces a
+	// remint on any audience/bound-object mismatch), so tr.Spec.Audiences is
+	// guaranteed to describe the token actually being returned here.
+	return status.Token, status.ExpirationTimestamp, tr.Spec.Audiences, rotated || !published, nil
+}
+
+// resolveBoundObjectRef validates that managed.Spec.BoundObjectRef, if set,
+// points at an object that actually exists in SpokeNamespace, and translates
+// it into the authv1.BoundObjectReference expected by the TokenRequest API.
+func (r *TokenReconciler) resolveBoundObjectRef(managed *authv1alpha1.ManagedServiceAccount) (*authv1.BoundObjectReference, error) {
+	ref := managed.Spec.BoundObjectRef
+	if ref == nil {
+		return nil, nil
+	}
+
+	switch ref.Kind {
+	case "Secret":
+		secret, err := r.SpokeNativeClient.CoreV1().Secrets(r.SpokeNamespace).
+			Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get bound secret %q", ref.Name)
+		}
+		return &authv1.BoundObjectReference{
+			Kind:       "Secret",
+			APIVersion: "v1",
+			Name:       secret.Name,
+			UID:        secret.UID,
+		}, nil
+	case "Pod":
+		pod, err := r.SpokeNativeClient.CoreV1().Pods(r.SpokeNamespace).
+			Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get bound pod %q", ref.Name)
+		}
+		return &authv1.BoundObjectReference{
+			Kind:       "Pod",
+			APIVersion: "v1",
+			Name:       pod.Name,
+			UID:        pod.UID,
+		}, nil
+	default:
+		return nil, errors.Errorf("unsupported bound object kind %q, expect Secret or Pod", ref.Kind)
 	}
-	return tr.Status.Token, tr.Status.ExpirationTimestamp, nil
 }
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultRotationThreshold mirrors the default remaining-lifetime window
+// under which a cached token is considered due for rotation.
+const defaultRotationThreshold = time.Hour * 24 * 15 // 15d
+
+// TokenGetter mints and caches service account tokens on the spoke cluster,
+// keyed by the target service account's namespaced name and audience-aware:
+// a cache hit also requires the cached token to have been minted for the
+// same audiences and bound object the caller is currently requesting. It
+// lets multiple callers share a token instead of each issuing its own
+// TokenRequest, which keeps us from hammering the spoke's TokenRequest API
+// on every reconcile.
+type TokenGetter struct {
+	SpokeNativeClient kubernetes.Interface
+
+	// RotationThreshold is the remaining token lifetime below which Get
+	// mints a fresh token instead of returning the cached one. Defaults
+	// to defaultRotationThreshold when zero.
+	RotationThreshold time.Duration
+
+	mu      sync.Mutex
+	entries map[types.NamespacedName]*cachedToken
+}
+
+// cachedToken pairs a cached TokenRequestStatus and the TokenRequestSpec it
+// was minted for with a lock, so that concurrent Get calls for the same
+// service account don't race to mint duplicate tokens.
+type cachedToken struct {
+	mu     sync.Mutex
+	status authv1.TokenRequestStatus
+	spec   authv1.TokenRequestSpec
+}
+
+// Get returns the cached token for key if it was minted for the same
+// audiences/bound object tr requests and its remaining lifetime is still
+// above the rotation threshold, minting a new one via TokenRequest
+// otherwise. The returned bool reports whether a new token was minted, so
+// callers can skip pushing an unchanged token to the hub.
+func (g *TokenGetter) Get(ctx context.Context, key types.NamespacedName, tr *authv1.TokenRequest) (*authv1.TokenRequestStatus, bool, error) {
+	entry := g.entryFor(key)
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if g.isFresh(entry.status) && specMatches(entry.spec, tr.Spec) {
+		return entry.status.DeepCopy(), false, nil
+	}
+
+	created, err := g.SpokeNativeClient.CoreV1().ServiceAccounts(key.Namespace).
+		CreateToken(ctx, key.Name, tr, metav1.CreateOptions{})
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to create token for service account %s", key)
+	}
+
+	entry.status = created.Status
+	entry.spec = tr.Spec
+	return entry.status.DeepCopy(), true, nil
+}
+
+func (g *TokenGetter) isFresh(status authv1.TokenRequestStatus) bool {
+	if len(status.Token) == 0 {
+		return false
+	}
+	threshold := g.RotationThreshold
+	if threshold == 0 {
+		threshold = defaultRotationThreshold
+	}
+	return time.Until(status.ExpirationTimestamp.Time) > threshold
+}
+
+// specMatches reports whether a token cached for spec cached is still valid
+// for a caller now requesting requested, so an operator editing
+// Spec.Audiences or Spec.BoundObjectRef forces an immediate remint instead
+// of silently keeping the old token until it happens to cross the rotation
+// threshold.
+func specMatches(cached, requested authv1.TokenRequestSpec) bool {
+	return reflect.DeepEqual(cached.Audiences, requested.Audiences) &&
+		reflect.DeepEqual(cached.BoundObjectRef, requested.BoundObjectRef)
+}
+
+func (g *TokenGetter) entryFor(key types.NamespacedName) *cachedToken {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.entries == nil {
+		g.entries = map[types.NamespacedName]*cachedToken{}
+	}
+	entry, ok := g.entries[key]
+	if !ok {
+		entry = &cachedToken{}
+		g.entries[key] = entry
+	}
+	return entry
+}
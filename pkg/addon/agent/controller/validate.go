@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	authv1alpha1 "open-cluster-management.io/managed-serviceaccount/api/v1alpha1"
+
+	"github.com/pkg/errors"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// validateToken confirms that token actually authenticates against the
+// spoke API server as the expected service account before it is published
+// to the hub. This catches broken CA data, clock skew, and misconfigured
+// audiences that would otherwise only surface once a downstream consumer
+// tries to use the token.
+//
+// SelfSubjectReview only reached GA in Kubernetes 1.30, so on older spokes
+// (including every spoke that lacks the TokenRequest API) this probe is
+// skipped entirely rather than failing status publication for an API the
+// spoke was never going to have. Legacy Secret-backed tokens on a spoke that
+// serves SelfSubjectReview are still probed.
+func (r *TokenReconciler) validateToken(ctx context.Context, managed *authv1alpha1.ManagedServiceAccount, token string, caData []byte) error {
+	if !r.SelfSubjectReviewSupported {
+		return nil
+	}
+
+	probeConfig := rest.CopyConfig(r.SpokeClientConfig)
+	probeConfig.BearerToken = token
+	probeConfig.BearerTokenFile = ""
+	probeConfig.Username = ""
+	probeConfig.Password = ""
+	probeConfig.AuthProvider = nil
+	probeConfig.ExecProvider = nil
+	probeConfig.TLSClientConfig = rest.TLSClientConfig{CAData: caData}
+
+	probeClient, err := kubernetes.NewForConfig(probeConfig)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build a probe client from the minted token")
+	}
+
+	review, err := probeClient.AuthenticationV1().SelfSubjectReviews().
+		Create(ctx, &authv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to verify the minted token against the spoke API server")
+	}
+
+	expected := fmt.Sprintf("system:serviceaccount:%s:%s", r.SpokeNamespace, managed.Name)
+	if review.Status.UserInfo.Username != expected {
+		return errors.Errorf("minted token authenticated as %q, expected %q", review.Status.UserInfo.Username, expected)
+	}
+	return nil
+}
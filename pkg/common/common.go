@@ -0,0 +1,8 @@
+package common
+
+const (
+	// LabelKeyIsManagedServiceAccount marks a native ServiceAccount on the
+	// spoke cluster as being owned/managed by a ManagedServiceAccount on
+	// the hub.
+	LabelKeyIsManagedServiceAccount = "authentication.open-cluster-management.io/is-managed-serviceaccount"
+)